@@ -0,0 +1,293 @@
+// Package doc menyediakan model dokumen berbasis span untuk teks bahasa
+// Indonesia, menggantikan pendekatan lama yang memindai ulang string mentah
+// dengan regexp di setiap tahap pipeline. Satu tokenizer memecah teks
+// menjadi Span bertipe; rule di paket puebi lalu memutasi pohon span itu
+// alih-alih string, sehingga mis. titik di dalam "Rp12.000" atau "dr." tidak
+// pernah tertukar dengan titik akhir kalimat.
+package doc
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kind mengidentifikasi jenis Span.
+type Kind int
+
+const (
+	KindText Kind = iota
+	KindWord
+	KindPunct
+	KindCurrency
+	KindNumber
+	KindQuote
+	KindSentenceBoundary
+	KindAbbreviation
+	KindURL
+	KindEmail
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindWord:
+		return "Word"
+	case KindPunct:
+		return "Punct"
+	case KindCurrency:
+		return "Currency"
+	case KindNumber:
+		return "Number"
+	case KindQuote:
+		return "Quote"
+	case KindSentenceBoundary:
+		return "SentenceBoundary"
+	case KindAbbreviation:
+		return "Abbreviation"
+	case KindURL:
+		return "URL"
+	case KindEmail:
+		return "Email"
+	default:
+		return "Text"
+	}
+}
+
+// Span adalah satu unit dalam Document. Implementasinya adalah pointer
+// struct (TextSpan, WordSpan, ...) agar Rule bisa memutasi isinya in-place.
+type Span interface {
+	Kind() Kind
+	String() string
+}
+
+// TextSpan adalah spasi atau karakter pengisi yang tidak diberi arti
+// khusus oleh tokenizer (dipertahankan apa adanya).
+type TextSpan struct{ Text string }
+
+func (s *TextSpan) Kind() Kind     { return KindText }
+func (s *TextSpan) String() string { return s.Text }
+
+// WordSpan adalah satu kata (huruf, boleh mengandung ' atau - internal).
+type WordSpan struct{ Text string }
+
+func (s *WordSpan) Kind() Kind     { return KindWord }
+func (s *WordSpan) String() string { return s.Text }
+
+// PunctSpan adalah satu tanda baca selain kutip dan akhir kalimat, mis.
+// koma, titik dua, kurung, em-dash, atau "..." hasil penggabungan elipsis.
+type PunctSpan struct{ Text string }
+
+func (s *PunctSpan) Kind() Kind     { return KindPunct }
+func (s *PunctSpan) String() string { return s.Text }
+
+// NumberSpan adalah rangkaian digit, boleh mengandung titik desimal/ribuan
+// (mis. "12.000"), yang bukan bagian dari CurrencySpan.
+type NumberSpan struct{ Text string }
+
+func (s *NumberSpan) Kind() Kind     { return KindNumber }
+func (s *NumberSpan) String() string { return s.Text }
+
+// QuoteSpan adalah satu karakter kutip ( ' atau " ).
+type QuoteSpan struct{ Text string }
+
+func (s *QuoteSpan) Kind() Kind     { return KindQuote }
+func (s *QuoteSpan) String() string { return s.Text }
+
+// SentenceBoundary adalah tanda akhir kalimat (. ! ?) yang bukan bagian dari
+// AbbreviationSpan atau angka desimal.
+type SentenceBoundary struct{ Text string }
+
+func (s *SentenceBoundary) Kind() Kind     { return KindSentenceBoundary }
+func (s *SentenceBoundary) String() string { return s.Text }
+
+// AbbreviationSpan adalah singkatan yang dikenal beserta titiknya (mis.
+// "dr.", "Jl.", "No.", "hlm."), sehingga titiknya tidak pernah dibaca
+// sebagai akhir kalimat oleh rule lain.
+type AbbreviationSpan struct{ Text string }
+
+func (s *AbbreviationSpan) Kind() Kind     { return KindAbbreviation }
+func (s *AbbreviationSpan) String() string { return s.Text }
+
+// URLSpan adalah satu tautan http(s):// atau www., diambil utuh sampai
+// spasi berikutnya sehingga titik/garis miring di dalamnya tidak pernah
+// dibaca sebagai SentenceBoundary atau CurrencySpan oleh rule lain.
+type URLSpan struct{ Text string }
+
+func (s *URLSpan) Kind() Kind     { return KindURL }
+func (s *URLSpan) String() string { return s.Text }
+
+// EmailSpan adalah satu alamat surel, dengan alasan yang sama dengan
+// URLSpan: titik pada domainnya bukan akhir kalimat.
+type EmailSpan struct{ Text string }
+
+func (s *EmailSpan) Kind() Kind     { return KindEmail }
+func (s *EmailSpan) String() string { return s.Text }
+
+// CurrencySpan adalah penanda mata uang ("Rp", "Rp.", "rp ", dst di Raw)
+// diikuti nominal di Amount, mis. Raw="Rp" Amount="12.000" untuk "Rp12.000".
+type CurrencySpan struct {
+	Raw    string
+	Amount string
+}
+
+func (s *CurrencySpan) Kind() Kind     { return KindCurrency }
+func (s *CurrencySpan) String() string { return s.Raw + s.Amount }
+
+// Document adalah rangkaian Span yang bila digabungkan (String) persis sama
+// dengan teks aslinya, sebelum ada rule yang memutasinya.
+type Document struct {
+	Spans []Span
+}
+
+// String menyerialkan Document kembali menjadi teks biasa.
+func (d *Document) String() string {
+	var b strings.Builder
+	for _, s := range d.Spans {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// reURLToken dan reEmailToken dicek lebih dulu dari segala tokenisasi lain
+// (termasuk scanCurrency) karena keduanya paling spesifik: "rp" di awal
+// domain atau digit di tengah path tidak boleh mematahkan URL/email jadi
+// span lain.
+var (
+	reURLToken   = regexp.MustCompile(`^(?i:https?://|www\.)\S+`)
+	reEmailToken = regexp.MustCompile(`^[\w.+-]+@[\w-]+\.[\w.-]+`)
+)
+
+// abbreviations adalah singkatan umum (tanpa titik, huruf kecil) yang
+// titiknya harus dianggap bagian dari singkatan, bukan akhir kalimat.
+var abbreviations = map[string]bool{
+	"dr": true, "drs": true, "dra": true, "prof": true,
+	"bpk": true, "ibu": true, "sdr": true, "sdri": true, "yth": true,
+	"jl": true, "no": true, "hlm": true, "tgl": true,
+	"dll": true, "dsb": true, "dkk": true, "cq": true, "ttd": true,
+}
+
+// Parse memecah s menjadi Document lewat satu kali pemindaian. Setiap
+// karakter input tercakup tepat satu kali oleh satu Span, sehingga
+// Document.String() pada hasilnya identik dengan s.
+func Parse(s string) *Document {
+	rs := []rune(s)
+	n := len(rs)
+	d := &Document{}
+	i := 0
+
+	for i < n {
+		r := rs[i]
+
+		if unicode.IsSpace(r) {
+			j := i
+			for j < n && unicode.IsSpace(rs[j]) {
+				j++
+			}
+			d.Spans = append(d.Spans, &TextSpan{Text: string(rs[i:j])})
+			i = j
+			continue
+		}
+
+		if j, ok := scanToken(reURLToken, rs, i); ok {
+			d.Spans = append(d.Spans, &URLSpan{Text: string(rs[i:j])})
+			i = j
+			continue
+		}
+
+		if j, ok := scanToken(reEmailToken, rs, i); ok {
+			d.Spans = append(d.Spans, &EmailSpan{Text: string(rs[i:j])})
+			i = j
+			continue
+		}
+
+		if j, ok := scanCurrency(rs, i); ok {
+			numStart, numEnd := j[1], j[2]
+			d.Spans = append(d.Spans, &CurrencySpan{
+				Raw:    string(rs[i:numStart]),
+				Amount: string(rs[numStart:numEnd]),
+			})
+			i = numEnd
+			continue
+		}
+
+		if unicode.IsLetter(r) {
+			j := i
+			for j < n && (unicode.IsLetter(rs[j]) || rs[j] == '\'' || rs[j] == '-') {
+				j++
+			}
+			word := string(rs[i:j])
+			if j < n && rs[j] == '.' && abbreviations[strings.ToLower(word)] {
+				d.Spans = append(d.Spans, &AbbreviationSpan{Text: word + "."})
+				i = j + 1
+				continue
+			}
+			d.Spans = append(d.Spans, &WordSpan{Text: word})
+			i = j
+			continue
+		}
+
+		if unicode.IsDigit(r) {
+			j := i
+			for j < n && (unicode.IsDigit(rs[j]) || (rs[j] == '.' && j+1 < n && unicode.IsDigit(rs[j+1]))) {
+				j++
+			}
+			d.Spans = append(d.Spans, &NumberSpan{Text: string(rs[i:j])})
+			i = j
+			continue
+		}
+
+		if r == '\'' || r == '"' {
+			d.Spans = append(d.Spans, &QuoteSpan{Text: string(r)})
+			i++
+			continue
+		}
+
+		if r == '.' || r == '!' || r == '?' {
+			d.Spans = append(d.Spans, &SentenceBoundary{Text: string(r)})
+			i++
+			continue
+		}
+
+		d.Spans = append(d.Spans, &PunctSpan{Text: string(r)})
+		i++
+	}
+
+	return d
+}
+
+// scanCurrency mengenali "rp"/"Rp"/"RP" (boleh diikuti "." dan/atau spasi)
+// lalu digit di posisi i. Hasilnya [akhirPrefixSaja, awalAngka, akhirAngka];
+// ok false bila i bukan awal penanda mata uang.
+func scanCurrency(rs []rune, i int) ([3]int, bool) {
+	n := len(rs)
+	if i+2 > n || !strings.EqualFold(string(rs[i:i+2]), "rp") {
+		return [3]int{}, false
+	}
+	j := i + 2
+	prefixEnd := j
+	if j < n && rs[j] == '.' {
+		j++
+	}
+	for j < n && rs[j] == ' ' {
+		j++
+	}
+	if j >= n || !unicode.IsDigit(rs[j]) {
+		return [3]int{}, false
+	}
+	numStart := j
+	for j < n && (unicode.IsDigit(rs[j]) || ((rs[j] == '.' || rs[j] == ',') && j+1 < n && unicode.IsDigit(rs[j+1]))) {
+		j++
+	}
+	return [3]int{prefixEnd, numStart, j}, true
+}
+
+// scanToken mencocokkan re (dijangkarkan "^") terhadap sisa teks mulai dari
+// i dan, bila cocok tepat di posisi i, mengembalikan indeks rune akhirnya.
+func scanToken(re *regexp.Regexp, rs []rune, i int) (int, bool) {
+	rest := string(rs[i:])
+	loc := re.FindStringIndex(rest)
+	if loc == nil || loc[0] != 0 {
+		return 0, false
+	}
+	return i + len([]rune(rest[:loc[1]])), true
+}
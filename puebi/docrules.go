@@ -0,0 +1,365 @@
+package puebi
+
+import (
+	"unicode"
+
+	"github.com/manhax/puebi/puebi/doc"
+)
+
+// Implementasi fixPunctuationSpacing, fixIDRCurrency, capitalizeSentences,
+// dan decapitalizeMidSentence di file ini beroperasi atas puebi/doc.Document
+// (span), bukan memindai ulang string mentah dengan regexp berlapis. Ini
+// menghindari tabrakan lama antara mis. titik desimal "Rp12.000" atau titik
+// singkatan "dr." dengan titik akhir kalimat, karena tokenizer tunggal sudah
+// memisahkannya menjadi CurrencySpan/AbbreviationSpan sejak awal.
+
+func fixPunctuationSpacing(s string) string {
+	d := doc.Parse(s)
+	d.Spans = mergeEllipsisSpans(d.Spans)
+	d.Spans = applySpacingRules(d.Spans)
+	return d.String()
+}
+
+// mergeEllipsisSpans menggabungkan satu rentetan SentenceBoundary "." dan/atau
+// karakter "…" yang berurutan menjadi satu PunctSpan "...", asalkan rentetan
+// itu mengandung "…" atau berbobot 3+ titik. "…" dan "." dilebur dalam SATU
+// pemindaian rentetan (bukan dua cabang terpisah) justru supaya mis. "…."
+// (elipsis diikuti satu titik literal) ikut terserap penuh menjadi "...": bila
+// hanya "…"-nya yang diganti lalu sisa "." dibiarkan, hasilnya "...." empat
+// karakter, yang pada pemindaian berikutnya dibaca ulang sebagai 4 titik dan
+// diringkas jadi "..." tiga karakter — tidak idempotent.
+func mergeEllipsisSpans(spans []doc.Span) []doc.Span {
+	out := make([]doc.Span, 0, len(spans))
+	i := 0
+	for i < len(spans) {
+		if isDotBoundary(spans[i]) || isEllipsisChar(spans[i]) {
+			j, weight, hasEllipsisChar := i, 0, false
+			for j < len(spans) && (isDotBoundary(spans[j]) || isEllipsisChar(spans[j])) {
+				if isEllipsisChar(spans[j]) {
+					hasEllipsisChar = true
+					weight += 3
+				} else {
+					weight++
+				}
+				j++
+			}
+			if hasEllipsisChar || weight >= 3 {
+				out = append(out, &doc.PunctSpan{Text: "..."})
+				i = j
+				continue
+			}
+		}
+		out = append(out, spans[i])
+		i++
+	}
+	return out
+}
+
+func isDotBoundary(sp doc.Span) bool {
+	b, ok := sp.(*doc.SentenceBoundary)
+	return ok && b.Text == "."
+}
+
+func isEllipsisChar(sp doc.Span) bool {
+	p, ok := sp.(*doc.PunctSpan)
+	return ok && p.Text == "…"
+}
+
+// applySpacingRules menghilangkan spasi yang tidak perlu sebelum tanda baca
+// penutup, merapatkan kurung/kutip/em-dash, dan menambahkan satu spasi
+// setelah tanda baca yang butuh jeda (koma, titik dua, titik akhir kalimat,
+// elipsis, dst) bila belum ada.
+func applySpacingRules(spans []doc.Span) []doc.Span {
+	out := make([]doc.Span, 0, len(spans))
+
+	for i := 0; i < len(spans); i++ {
+		sp := spans[i]
+
+		if t, ok := sp.(*doc.TextSpan); ok {
+			var next doc.Span
+			if i+1 < len(spans) {
+				next = spans[i+1]
+			}
+			if t.Text == "" || dropsSpaceBefore(next) || opensTight(lastSpan(out)) {
+				continue
+			}
+			out = append(out, t)
+			continue
+		}
+
+		// elipsis butuh spasi kiri bila sebelumnya bukan spasi/pembuka
+		if p, ok := sp.(*doc.PunctSpan); ok && p.Text == "..." {
+			if prev := lastSpan(out); prev != nil && !isTextSpan(prev) && !opensTight(prev) {
+				out = append(out, &doc.TextSpan{Text: " "})
+			}
+		}
+
+		out = append(out, sp)
+
+		if i+1 < len(spans) {
+			if _, isText := spans[i+1].(*doc.TextSpan); isText {
+				continue
+			}
+			if needsSpaceAfter(sp, spans[i+1]) {
+				out = append(out, &doc.TextSpan{Text: " "})
+			}
+		}
+	}
+	return out
+}
+
+func lastSpan(spans []doc.Span) doc.Span {
+	if len(spans) == 0 {
+		return nil
+	}
+	return spans[len(spans)-1]
+}
+
+func isTextSpan(sp doc.Span) bool {
+	_, ok := sp.(*doc.TextSpan)
+	return ok
+}
+
+// opensTight: "(", kutip, dan em-dash tidak diikuti spasi sesudahnya. Kutip
+// pembuka dan penutup tidak dibedakan: spasi setelah kutip apa pun dibuang.
+func opensTight(sp doc.Span) bool {
+	switch v := sp.(type) {
+	case *doc.PunctSpan:
+		return v.Text == "(" || v.Text == "—"
+	case *doc.QuoteSpan:
+		return true
+	}
+	return false
+}
+
+// dropsSpaceBefore: tanda baca penutup ini tidak boleh didahului spasi.
+func dropsSpaceBefore(next doc.Span) bool {
+	switch v := next.(type) {
+	case *doc.PunctSpan:
+		switch v.Text {
+		case ",", ";", ":", ")", "—":
+			return true
+		}
+	case *doc.SentenceBoundary:
+		return true
+	case *doc.QuoteSpan:
+		return true
+	}
+	return false
+}
+
+// closesTight: span ini tidak butuh spasi sebelum dirinya, jadi rule "tambah
+// spasi setelah X" harus berhenti bila elemen berikutnya salah satu ini.
+func closesTight(next doc.Span) bool {
+	if p, ok := next.(*doc.PunctSpan); ok {
+		return p.Text == ")" || p.Text == "..."
+	}
+	if _, ok := next.(*doc.SentenceBoundary); ok {
+		return true
+	}
+	if _, ok := next.(*doc.QuoteSpan); ok {
+		return true
+	}
+	return false
+}
+
+func needsSpaceAfter(cur, next doc.Span) bool {
+	switch v := cur.(type) {
+	case *doc.PunctSpan:
+		switch v.Text {
+		case ",", ";", ":", "...":
+			return !closesTight(next)
+		}
+		return false
+	case *doc.SentenceBoundary:
+		if v.Text == "." {
+			// hindari memecah angka seperti pada "kalimat.5 meter"
+			if _, isNum := next.(*doc.NumberSpan); isNum {
+				return false
+			}
+		}
+		return !closesTight(next)
+	}
+	return false
+}
+
+// fixIDRCurrency menormalkan penanda mata uang pada setiap CurrencySpan
+// menjadi "Rp" tanpa titik/spasi, mis. "Rp. 12.000"/"rp12.000" -> "Rp12.000".
+func fixIDRCurrency(s string) string {
+	d := doc.Parse(s)
+	for _, sp := range d.Spans {
+		if c, ok := sp.(*doc.CurrencySpan); ok {
+			c.Raw = "Rp"
+		}
+	}
+	return d.String()
+}
+
+// capitalizeSentences mengapitalkan huruf pertama dokumen dan huruf pertama
+// setelah setiap SentenceBoundary. Titik pada AbbreviationSpan ("dr.", "Jl.")
+// tidak pernah dianggap SentenceBoundary, sehingga kata sesudahnya tidak
+// ikut terkapitalisasi secara keliru.
+func capitalizeSentences(s string) string {
+	return capitalizeSentencesScoped(s, nil)
+}
+
+// capitalizeSentencesScoped berfungsi seperti capitalizeSentences, tetapi
+// tidak pernah mengapitalkan span yang tumpang tindih dengan protected
+// (ruas bukan Indonesia menurut puebi/lang saat Pipeline.IgnoreForeign
+// aktif) — pencarian "kata pertama kalimat" melompati span asing itu
+// alih-alih berhenti di situ, supaya mis. URL di awal kalimat tidak
+// dikapitalkan tetapi kata Indonesia sesudahnya tetap benar.
+func capitalizeSentencesScoped(s string, protected [][2]int) string {
+	d := doc.Parse(s)
+	offsets := spanOffsets(d.Spans)
+	capitalizeFirstWordFromScoped(d.Spans, 0, offsets, protected)
+	for i, sp := range d.Spans {
+		if _, ok := sp.(*doc.SentenceBoundary); ok {
+			capitalizeFirstWordFromScoped(d.Spans, i+1, offsets, protected)
+		}
+	}
+	return d.String()
+}
+
+// spanOffsets mengembalikan offset byte kumulatif setiap span dalam spans,
+// sehingga offsets[i] dan offsets[i+1] adalah rentang [start,end) span ke-i
+// dalam string asal Document.String().
+func spanOffsets(spans []doc.Span) []int {
+	offsets := make([]int, len(spans)+1)
+	for i, sp := range spans {
+		offsets[i+1] = offsets[i] + len(sp.String())
+	}
+	return offsets
+}
+
+// capitalizeFirstWordFrom mengapitalkan huruf pertama dari span pertama yang
+// bukan TextSpan (spasi) mulai dari from, lalu berhenti — baik span itu kata,
+// singkatan, prefiks mata uang seperti "Rp", atau (bila bukan salah satu itu)
+// tidak diapa-apakan sama sekali. Pencarian TIDAK melompati span bukan-kata
+// seperti NumberSpan/PunctSpan untuk mencari huruf lebih jauh, supaya kalimat
+// yang diawali angka/tanda baca tidak membuat huruf yang terkubur jauh di
+// tengahnya ikut terkapitalkan.
+func capitalizeFirstWordFrom(spans []doc.Span, from int) {
+	capitalizeFirstWordFromScoped(spans, from, spanOffsets(spans), nil)
+}
+
+// capitalizeFirstWordFromScoped berfungsi seperti capitalizeFirstWordFrom,
+// tetapi melompati (bukan berhenti di) span yang tumpang tindih dengan
+// protected, karena span asing yang kebetulan ada di awal kalimat bukan
+// kandidat kapitalisasi yang sah — pencarian lanjut ke kata Indonesia
+// berikutnya. Selain TextSpan (spasi murni) dan span protected, span apa
+// pun lain (angka, tanda baca, kutip, URL/email yang tidak protected, dst)
+// menghentikan pencarian di situ tanpa mengapitalkan apa pun: sebelumnya
+// span-span ini dilewati begitu saja, sehingga mis. "kalimat! 0000a0000..."
+// membuat huruf "a" yang terkubur jauh di tengah deretan digit malah
+// terkapitalkan — itu bukan hanya salah, tapi juga membuat SanitizeToPUEBI
+// tidak idempotent (token "di"/"ke" yang membaca token tetangganya pada
+// lintasan berikutnya melihat kapitalisasi yang sudah berubah).
+func capitalizeFirstWordFromScoped(spans []doc.Span, from int, offsets []int, protected [][2]int) {
+	for i := from; i < len(spans); i++ {
+		if _, ok := spans[i].(*doc.TextSpan); ok {
+			continue
+		}
+		if overlapsRange(protected, offsets[i], offsets[i+1]) {
+			continue
+		}
+		switch v := spans[i].(type) {
+		case *doc.WordSpan:
+			v.Text = capitalizeFirst(v.Text)
+		case *doc.AbbreviationSpan:
+			v.Text = capitalizeFirst(v.Text)
+		case *doc.CurrencySpan:
+			v.Raw = capitalizeFirst(v.Raw)
+		}
+		return
+	}
+}
+
+func capitalizeFirst(w string) string {
+	rs := []rune(w)
+	if len(rs) == 0 {
+		return w
+	}
+	rs[0] = unicode.ToUpper(rs[0])
+	return string(rs)
+}
+
+// decapitalizeMidSentence menurunkan kapital kata Title Case tunggal yang
+// nyasar di tengah kalimat (bukan kata pertama), kecuali kata tersebut ALL
+// CAPS (akronim), masuk daftar exceptions, atau mengikuti head proper-noun
+// di heads (mis. "Jalan Sudirman"). Batas kalimat memakai SentenceBoundary,
+// sehingga titik milik AbbreviationSpan/CurrencySpan tidak memotong kalimat.
+func decapitalizeMidSentence(s string, exceptions map[string]bool, heads map[string]bool) string {
+	return decapitalizeMidSentenceScoped(s, exceptions, heads, nil)
+}
+
+// decapitalizeMidSentenceScoped berfungsi seperti decapitalizeMidSentence,
+// tetapi juga memperlakukan kata yang tumpang tindih dengan protected
+// (ruas bukan Indonesia menurut puebi/lang saat Pipeline.IgnoreForeign
+// aktif) seperti exceptions: tidak pernah diturunkan kapitalnya, karena
+// aturan Title Case bahasa Indonesia tidak berlaku untuk kata asing.
+func decapitalizeMidSentenceScoped(s string, exceptions map[string]bool, heads map[string]bool, protected [][2]int) string {
+	d := doc.Parse(s)
+	offsets := spanOffsets(d.Spans)
+
+	isAllCaps := func(w string) bool {
+		has := false
+		for _, r := range w {
+			if unicode.IsLetter(r) {
+				has = true
+				if !unicode.IsUpper(r) {
+					return false
+				}
+			}
+		}
+		return has
+	}
+	isTitleCase := func(w string) bool {
+		rs := []rune(w)
+		if len(rs) == 0 || !unicode.IsUpper(rs[0]) {
+			return false
+		}
+		for i := 1; i < len(rs); i++ {
+			if unicode.IsLetter(rs[i]) && !unicode.IsLower(rs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var prevWord string
+	firstOfSentence := true
+	for i, sp := range d.Spans {
+		if _, ok := sp.(*doc.SentenceBoundary); ok {
+			firstOfSentence = true
+			prevWord = ""
+			continue
+		}
+		w, ok := sp.(*doc.WordSpan)
+		if !ok {
+			continue
+		}
+		if firstOfSentence {
+			firstOfSentence = false
+			prevWord = w.Text
+			continue
+		}
+		if isAllCaps(w.Text) || exceptions[w.Text] || heads[prevWord] || overlapsRange(protected, offsets[i], offsets[i+1]) {
+			prevWord = w.Text
+			continue
+		}
+		if isTitleCase(w.Text) {
+			w.Text = toLowerASCIIAware(w.Text)
+		}
+		prevWord = w.Text
+	}
+	return d.String()
+}
+
+func toLowerASCIIAware(w string) string {
+	rs := []rune(w)
+	for i, r := range rs {
+		rs[i] = unicode.ToLower(r)
+	}
+	return string(rs)
+}
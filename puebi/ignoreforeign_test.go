@@ -0,0 +1,11 @@
+package puebi
+
+import "testing"
+
+func TestPipelineIgnoreForeignProtectsEnglishAndEmail(t *testing.T) {
+	input := "Jangan reply email ini, hubungi support@bank.com sekarang."
+	got := DefaultPipeline().IgnoreForeign(true).Run(input)
+	if got != input {
+		t.Errorf("IgnoreForeign(true).Run() changed protected text:\n got:  %q\n want: %q", got, input)
+	}
+}
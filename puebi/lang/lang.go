@@ -0,0 +1,253 @@
+// Package lang menyediakan segmentasi ringan teks campuran Indonesia/asing,
+// dipakai puebi untuk melewatkan (pass-through) ruas bahasa Inggris, kode,
+// URL, email, dan nomor tanpa diutak-atik oleh rule pemaknaan seperti
+// kapitalisasi atau preposisi di-/ke-.
+package lang
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed lexicon.txt
+var lexiconData string
+
+// Kind menyatakan bahasa/jenis satu Segment.
+type Kind int
+
+const (
+	// KindIndonesian adalah ruas yang dianggap teks Indonesia biasa dan
+	// tunduk pada seluruh rule pemaknaan puebi.
+	KindIndonesian Kind = iota
+	// KindEnglish adalah ruas yang dikenali sebagai bahasa Inggris dan
+	// sebaiknya tidak dikapitalisasi/dipisah ulang sebagai bahasa Indonesia.
+	KindEnglish
+	// KindCode adalah identifier/penggalan kode (snake_case, camelCase,
+	// atau teks berpagar backtick).
+	KindCode
+	// KindURL adalah tautan http(s):// atau www.
+	KindURL
+	// KindEmail adalah alamat surel atau handle @pengguna.
+	KindEmail
+	// KindNumber adalah rangkaian digit, boleh mengandung titik/koma
+	// desimal atau ribuan.
+	KindNumber
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindEnglish:
+		return "English"
+	case KindCode:
+		return "Code"
+	case KindURL:
+		return "URL"
+	case KindEmail:
+		return "Email"
+	case KindNumber:
+		return "Number"
+	default:
+		return "Indonesian"
+	}
+}
+
+// Segment adalah satu ruas teks dan bahasa/jenisnya. Menyambung Text dari
+// seluruh Segment hasil Detect(s) persis mengembalikan s.
+type Segment struct {
+	Kind Kind
+	Text string
+}
+
+var (
+	reURL      = regexp.MustCompile(`^(?i:https?://|www\.)\S+`)
+	reEmail    = regexp.MustCompile(`^[\w.+-]+@[\w-]+\.[\w.-]+`)
+	reHandle   = regexp.MustCompile(`^@\w+`)
+	reCodeSpan = regexp.MustCompile("^`[^`]+`")
+)
+
+var (
+	idWords map[string]bool
+	enWords map[string]bool
+)
+
+func init() {
+	idWords = make(map[string]bool)
+	enWords = make(map[string]bool)
+	for _, line := range strings.Split(lexiconData, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		word, kind := parts[0], parts[1]
+		switch kind {
+		case "id":
+			idWords[word] = true
+		case "en":
+			enWords[word] = true
+		}
+	}
+}
+
+// Detect memecah s menjadi Segment berurutan. Penggalan kode berpagar
+// backtick, URL, email, dan handle @ dikenali lebih dulu (prioritas di atas
+// klasifikasi kata), lalu angka, dan akhirnya kata yang diklasifikasikan
+// Indonesia/Inggris/kode lewat classifyWord. Spasi dan tanda baca
+// disambungkan ke Segment sebelumnya agar Detect tidak pernah menambah
+// Segment hanya untuk pemisah.
+func Detect(s string) []Segment {
+	rs := []rune(s)
+	n := len(rs)
+	var segs []Segment
+
+	push := func(kind Kind, text string) {
+		if text == "" {
+			return
+		}
+		if len(segs) > 0 && segs[len(segs)-1].Kind == kind {
+			segs[len(segs)-1].Text += text
+			return
+		}
+		segs = append(segs, Segment{Kind: kind, Text: text})
+	}
+
+	attach := func(text string) {
+		if len(segs) == 0 {
+			segs = append(segs, Segment{Kind: KindIndonesian, Text: text})
+			return
+		}
+		segs[len(segs)-1].Text += text
+	}
+
+	i := 0
+	for i < n {
+		rest := string(rs[i:])
+
+		if loc := reCodeSpan.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+			j := i + len([]rune(rest[:loc[1]]))
+			push(KindCode, string(rs[i:j]))
+			i = j
+			continue
+		}
+		if loc := reURL.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+			j := i + len([]rune(rest[:loc[1]]))
+			push(KindURL, string(rs[i:j]))
+			i = j
+			continue
+		}
+		if loc := reEmail.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+			j := i + len([]rune(rest[:loc[1]]))
+			push(KindEmail, string(rs[i:j]))
+			i = j
+			continue
+		}
+		if loc := reHandle.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+			j := i + len([]rune(rest[:loc[1]]))
+			push(KindEmail, string(rs[i:j]))
+			i = j
+			continue
+		}
+
+		r := rs[i]
+		if unicode.IsDigit(r) {
+			j := i
+			for j < n && (unicode.IsDigit(rs[j]) || ((rs[j] == '.' || rs[j] == ',') && j+1 < n && unicode.IsDigit(rs[j+1]))) {
+				j++
+			}
+			push(KindNumber, string(rs[i:j]))
+			i = j
+			continue
+		}
+
+		if unicode.IsLetter(r) {
+			j := i
+			for j < n && (unicode.IsLetter(rs[j]) || rs[j] == '\'' || rs[j] == '-' || rs[j] == '_') {
+				j++
+			}
+			word := string(rs[i:j])
+			push(classifyWord(word), word)
+			i = j
+			continue
+		}
+
+		attach(string(r))
+		i++
+	}
+
+	return segs
+}
+
+// classifyWord menentukan Kind satu token kata (bukan angka/URL/email).
+// Urutan sinyal: penanda kode (underscore/camelCase) dulu, lalu kecocokan
+// leksikon stopword, lalu heuristik afiks Indonesia/Inggris, dan baru jatuh
+// ke Indonesia sebagai default netral (konsisten dengan asumsi lama puebi
+// bahwa seluruh dokumen berbahasa Indonesia kecuali terbukti sebaliknya).
+func classifyWord(word string) Kind {
+	if strings.ContainsRune(word, '_') || isCamelCase(word) {
+		return KindCode
+	}
+
+	lower := strings.ToLower(word)
+	if idWords[lower] {
+		return KindIndonesian
+	}
+	if enWords[lower] {
+		return KindEnglish
+	}
+	if hasIndonesianAffix(lower) {
+		return KindIndonesian
+	}
+	if hasEnglishSuffix(lower) {
+		return KindEnglish
+	}
+	return KindIndonesian
+}
+
+// isCamelCase mendeteksi transisi huruf kecil->besar di tengah kata (mis.
+// "realTime", "parseURL"), bukan sekadar huruf pertama kapital seperti nama
+// diri ("Luqman"), supaya nama orang/tempat tidak salah ditandai Code.
+func isCamelCase(word string) bool {
+	sawLower := false
+	for _, r := range word {
+		if unicode.IsUpper(r) && sawLower {
+			return true
+		}
+		if unicode.IsLower(r) {
+			sawLower = true
+		}
+	}
+	return false
+}
+
+var indonesianPrefixes = []string{"di", "ke", "me", "ber", "ter", "per", "se", "pe"}
+var indonesianSuffixes = []string{"kan", "lah", "kah", "nya", "mu", "ku"}
+
+func hasIndonesianAffix(lower string) bool {
+	for _, suf := range indonesianSuffixes {
+		if strings.HasSuffix(lower, suf) && len(lower)-len(suf) >= 2 {
+			return true
+		}
+	}
+	for _, pre := range indonesianPrefixes {
+		if strings.HasPrefix(lower, pre) && len(lower)-len(pre) >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+var englishSuffixes = []string{"tion", "sion", "ment", "ness", "ing", "ly", "ed"}
+
+func hasEnglishSuffix(lower string) bool {
+	for _, suf := range englishSuffixes {
+		if strings.HasSuffix(lower, suf) && len(lower)-len(suf) >= 2 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package lang
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectRoundTrip(t *testing.T) {
+	inputs := []string{
+		"Jangan reply email ini, hubungi support@bank.com sekarang.",
+		"Kunjungi https://bank.com/Help untuk info lebih lanjut.",
+		"Ini kalimat biasa tanpa istilah asing.",
+	}
+	for _, in := range inputs {
+		var got string
+		for _, seg := range Detect(in) {
+			got += seg.Text
+		}
+		if got != in {
+			t.Errorf("Detect(%q) segments do not reassemble to input, got %q", in, got)
+		}
+	}
+}
+
+func TestDetectTagsEnglishAndEmail(t *testing.T) {
+	segs := Detect("Jangan reply email ini, hubungi support@bank.com sekarang.")
+
+	var kinds []Kind
+	for _, seg := range segs {
+		kinds = append(kinds, seg.Kind)
+	}
+
+	// attach() melekatkan spasi/tanda baca berikutnya ke Segment sebelumnya,
+	// jadi cocokkan dengan Contains, bukan persamaan persis.
+	foundEnglish, foundEmail, foundIndonesian := false, false, false
+	for _, seg := range segs {
+		switch {
+		case seg.Kind == KindEnglish && strings.Contains(seg.Text, "reply") && strings.Contains(seg.Text, "email"):
+			foundEnglish = true
+		case seg.Kind == KindEmail && strings.Contains(seg.Text, "support@bank.com"):
+			foundEmail = true
+		case seg.Kind == KindIndonesian && strings.Contains(seg.Text, "ini"):
+			foundIndonesian = true
+		}
+	}
+	if !foundEnglish {
+		t.Errorf("expected an English segment for reply/email, got kinds %v", kinds)
+	}
+	if !foundEmail {
+		t.Errorf("expected an Email segment for support@bank.com, got kinds %v", kinds)
+	}
+	if !foundIndonesian {
+		t.Errorf("expected \"ini\" to be tagged Indonesian, got kinds %v", kinds)
+	}
+}
+
+func TestDetectTagsURL(t *testing.T) {
+	segs := Detect("Kunjungi https://bank.com/Help sekarang.")
+	found := false
+	for _, seg := range segs {
+		if seg.Kind == KindURL && strings.Contains(seg.Text, "https://bank.com/Help") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a URL segment for https://bank.com/Help, got %+v", segs)
+	}
+}
+
+func TestDetectTagsCode(t *testing.T) {
+	segs := Detect("Panggil parseURL atau snake_case_fn sekarang.")
+	var codeWords []string
+	for _, seg := range segs {
+		if seg.Kind == KindCode {
+			codeWords = append(codeWords, seg.Text)
+		}
+	}
+	if len(codeWords) != 2 {
+		t.Errorf("expected 2 Code segments, got %v", codeWords)
+	}
+}
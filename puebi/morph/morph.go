@@ -0,0 +1,200 @@
+// Package morph menyediakan analisis morfologi ringan untuk bahasa Indonesia,
+// dipakai puebi untuk membedakan "di"/"ke" sebagai preposisi (ditulis
+// terpisah) dari "di"/"ke" sebagai prefiks verba/nomina (ditulis serangkai).
+package morph
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+//go:embed roots.txt
+var rootsData string
+
+// Category menyatakan peran "di"/"ke" pada sebuah token.
+type Category int
+
+const (
+	// CategoryUnknown berarti kata dasar tidak dikenali; pemanggil
+	// sebaiknya mempertahankan perilaku lama (dibiarkan serangkai).
+	CategoryUnknown Category = iota
+	// CategoryPreposition berarti "di"/"ke" berfungsi sebagai preposisi
+	// dan harus dipisah dari kata yang mengikutinya.
+	CategoryPreposition
+	// CategoryPrefix berarti "di"/"ke" adalah bagian dari kata berimbuhan
+	// dan harus tetap serangkai.
+	CategoryPrefix
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryPreposition:
+		return "preposition"
+	case CategoryPrefix:
+		return "prefix"
+	default:
+		return "unknown"
+	}
+}
+
+// TokenInfo adalah hasil analisis morfologi satu token.
+type TokenInfo struct {
+	Root     string   // kata dasar setelah prefiks & sufiks dilepas
+	Prefix   string   // "di", "ke", atau "" jika token tidak berawalan itu
+	Suffixes []string // sufiks/klitik yang dilepas, urut dari terluar
+	Category Category
+}
+
+// clitics dilepas dari terluar ke terdalam: partikel, lalu klitik
+// posesif/pronomina, lalu sufiks derivasional.
+var clitics = []string{"lah", "kah", "nya", "ku", "mu", "kan", "i"}
+
+var (
+	places map[string]bool
+	verbs  map[string]bool
+)
+
+func init() {
+	places = make(map[string]bool)
+	verbs = make(map[string]bool)
+	for _, line := range strings.Split(rootsData, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		word, cat := parts[0], parts[1]
+		switch cat {
+		case "place":
+			places[word] = true
+		case "verb":
+			verbs[word] = true
+		}
+	}
+}
+
+// maxSuffixDepth membatasi berapa kali stripSuffixes boleh melepas sufiks
+// sebelum menyerah pada pelepasan rakus lama; rangkaian sufiks Indonesia
+// nyata jarang lebih dari dua lapis (mis. derivasional + klitik posesif).
+const maxSuffixDepth = 3
+
+// stripSuffixes melepas sufiks/klitik dari akhir kata, mengembalikan akar
+// kata dan daftar sufiks yang dilepas (urut terluar dulu). Pelepasan
+// berhenti di kandidat akar pertama yang dikenal di roots.txt, bukan
+// sekadar melepas substring yang kebetulan cocok dengan klitik sampai tak
+// ada lagi yang cocok: akar seperti "sekolah" kebetulan berakhiran "lah",
+// dan pelepasan rakus tanpa validasi kamus akan memotongnya jadi "seko"
+// meski tidak ada klitik "lah" yang sesungguhnya di sana. Bila tak ada
+// kandidat yang dikenal sampai maxSuffixDepth, jatuh ke pelepasan rakus
+// lama supaya kata yang memang tak dikenal tetap berperilaku seperti
+// sebelumnya (Category jatuh ke Unknown/heuristik konteks).
+func stripSuffixes(word string) (root string, suffixes []string) {
+	candidate := word
+	var chain []string
+	for depth := 0; depth <= maxSuffixDepth; depth++ {
+		if isKnownRoot(candidate) {
+			return candidate, chain
+		}
+		next, suf, ok := stripOneSuffix(candidate)
+		if !ok {
+			break
+		}
+		candidate = next
+		chain = append(chain, suf)
+	}
+	return stripSuffixesGreedy(word)
+}
+
+func isKnownRoot(w string) bool {
+	return places[w] || verbs[w]
+}
+
+// stripOneSuffix melepas klitik pertama (urutan clitics) yang cocok dengan
+// ekor root, bila sisa akar masih punya panjang wajar (>=2 huruf).
+func stripOneSuffix(root string) (next, suf string, ok bool) {
+	for _, clitic := range clitics {
+		if strings.HasSuffix(root, clitic) && len(root)-len(clitic) >= 2 {
+			return root[:len(root)-len(clitic)], clitic, true
+		}
+	}
+	return "", "", false
+}
+
+// stripSuffixesGreedy adalah perilaku pelepasan lama: lepas klitik demi
+// klitik sampai tak ada lagi yang cocok, tanpa validasi kamus. Dipakai
+// sebagai fallback stripSuffixes bila tak ada akar yang dikenal ditemukan.
+func stripSuffixesGreedy(word string) (root string, suffixes []string) {
+	root = word
+	for {
+		next, suf, ok := stripOneSuffix(root)
+		if !ok {
+			break
+		}
+		root = next
+		suffixes = append(suffixes, suf)
+	}
+	return root, suffixes
+}
+
+// Analyze menganalisis satu token dan menentukan apakah awalan "di"/"ke"
+// berperan sebagai preposisi atau prefiks, tanpa melihat konteks kalimat.
+// Gunakan AnalyzeInContext bila token berikutnya tersedia, karena beberapa
+// kasus (mis. "di Jakarta") hanya bisa diputuskan dari heuristik POS token
+// tetangga.
+func Analyze(token string) TokenInfo {
+	return AnalyzeInContext(token, "")
+}
+
+// AnalyzeInContext berfungsi seperti Analyze, tetapi menerima token yang
+// mengikuti (boleh kosong) untuk heuristik POS fallback: bila akar tidak
+// dikenal dan token berikutnya diawali huruf kapital (indikasi nama diri),
+// "di"/"ke" diperlakukan sebagai preposisi diikuti nomina proper (mis.
+// "di Jakarta", "ke Surabaya").
+func AnalyzeInContext(token, next string) TokenInfo {
+	lower := strings.ToLower(token)
+
+	var prefix string
+	switch {
+	case strings.HasPrefix(lower, "di"):
+		prefix = "di"
+	case strings.HasPrefix(lower, "ke"):
+		prefix = "ke"
+	default:
+		return TokenInfo{Root: token, Category: CategoryUnknown}
+	}
+
+	// rest boleh kosong (token persis "di"/"ke"); stripSuffixes dan
+	// isKnownRoot menangani root kosong dengan aman (tak pernah cocok di
+	// roots.txt), sehingga token bare ini tetap lanjut ke heuristik POS di
+	// bawah alih-alih berhenti di CategoryUnknown sebelum sempat melihat next.
+	rest := lower[len(prefix):]
+	root, suffixes := stripSuffixes(rest)
+
+	info := TokenInfo{Prefix: prefix, Root: root, Suffixes: suffixes}
+
+	switch {
+	case places[root]:
+		info.Category = CategoryPreposition
+	case verbs[root]:
+		info.Category = CategoryPrefix
+	case startsUpper(next):
+		info.Category = CategoryPreposition
+	default:
+		info.Category = CategoryUnknown
+	}
+
+	return info
+}
+
+func startsUpper(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	r := []rune(s)[0]
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,46 @@
+package morph
+
+import "testing"
+
+func TestAnalyzeInContextPreposition(t *testing.T) {
+	info := AnalyzeInContext("dirumahku", "")
+	if info.Category != CategoryPreposition {
+		t.Errorf("AnalyzeInContext(%q) = %v, want %v", "dirumahku", info.Category, CategoryPreposition)
+	}
+	if info.Root != "rumah" {
+		t.Errorf("Root = %q, want %q", info.Root, "rumah")
+	}
+}
+
+func TestAnalyzeInContextPrefix(t *testing.T) {
+	info := AnalyzeInContext("dibayar", "")
+	if info.Category != CategoryPrefix {
+		t.Errorf("AnalyzeInContext(%q) = %v, want %v", "dibayar", info.Category, CategoryPrefix)
+	}
+}
+
+func TestAnalyzeInContextKnownRootStopsSuffixStrip(t *testing.T) {
+	// "sekolah" kebetulan berakhiran klitik "lah"; tanpa validasi kamus di
+	// stripSuffixes, ini akan dipotong jadi root palsu "seko".
+	info := AnalyzeInContext("kesekolahnya", "")
+	if info.Category != CategoryPreposition {
+		t.Errorf("AnalyzeInContext(%q) = %v, want %v", "kesekolahnya", info.Category, CategoryPreposition)
+	}
+	if info.Root != "sekolah" {
+		t.Errorf("Root = %q, want %q", info.Root, "sekolah")
+	}
+}
+
+func TestAnalyzeInContextUnknownRootFallsBackToProperNounHeuristic(t *testing.T) {
+	info := AnalyzeInContext("di", "Jakarta")
+	if info.Category != CategoryPreposition {
+		t.Errorf("AnalyzeInContext(%q, %q) = %v, want %v", "di", "Jakarta", info.Category, CategoryPreposition)
+	}
+}
+
+func TestAnalyzeNoPrefixIsUnknown(t *testing.T) {
+	info := Analyze("makan")
+	if info.Category != CategoryUnknown {
+		t.Errorf("Analyze(%q) = %v, want %v", "makan", info.Category, CategoryUnknown)
+	}
+}
@@ -0,0 +1,263 @@
+package puebi
+
+import (
+	"strings"
+
+	"github.com/manhax/puebi/puebi/lang"
+)
+
+// Document adalah keadaan teks yang dioperasikan oleh Rule di sepanjang
+// Pipeline. Representasinya sengaja disederhanakan menjadi string mentah;
+// lihat paket puebi/doc untuk model span yang lebih kaya yang akan
+// menggantikan internal ini tanpa mengubah API publik di bawah.
+type Document struct {
+	text string
+}
+
+// NewDocument membungkus s sebagai Document awal.
+func NewDocument(s string) *Document {
+	return &Document{text: s}
+}
+
+// String mengembalikan isi Document saat ini.
+func (d *Document) String() string {
+	return d.text
+}
+
+// Set mengganti isi Document. Dipanggil oleh Rule setelah transformasi.
+func (d *Document) Set(s string) {
+	d.text = s
+}
+
+// Rule adalah satu langkah transformasi bernama yang beroperasi atas Document.
+type Rule interface {
+	Name() string
+	Apply(d *Document)
+}
+
+// funcRule mengadaptasi fungsi string->string lama (pra-Pipeline) menjadi Rule.
+type funcRule struct {
+	name string
+	fn   func(string) string
+}
+
+func (r funcRule) Name() string      { return r.name }
+func (r funcRule) Apply(d *Document) { d.Set(r.fn(d.String())) }
+
+// newRule membungkus fn sebagai Rule bernama name.
+func newRule(name string, fn func(string) string) Rule {
+	return funcRule{name: name, fn: fn}
+}
+
+// Rule bawaan, dipakai DefaultPipeline dan tersedia untuk disusun ulang oleh
+// pemanggil (mis. pipeline khusus per-bank yang menambah rule proteksi nama
+// produk di antara FixPrepositions dan CapitalizeSentences).
+var (
+	NormalizeSpaces         = newRule("NormalizeSpaces", normalizeSpaces)
+	FixPunctuationSpacing   = newRule("FixPunctuationSpacing", fixPunctuationSpacing)
+	FixPrepositions         = newRule("FixPrepositions", fixCommonPrepositions)
+	NormalizeRealTime       = newRule("NormalizeRealTime", normalizeRealTime)
+	CapitalizeSentences     = newRule("CapitalizeSentences", capitalizeSentences)
+	FixGreetingNameCase     = newRule("FixGreetingNameCase", fixGreetingNameCase)
+	DecapitalizeMidSentence = newRule("DecapitalizeMidSentence", func(s string) string {
+		return decapitalizeMidSentence(s, defaultExceptions(), protectedHeads())
+	})
+	FixIDRCurrency = newRule("FixIDRCurrency", fixIDRCurrency)
+)
+
+// scopedRuleFns memetakan nama Rule pemaknaan ke varian "Scoped"-nya, dipakai
+// run() saat Pipeline.IgnoreForeign aktif. Setiap varian Scoped menerima
+// rentang byte protected (ruas bukan Indonesia hasil lang.Detect) dan
+// dijamin tidak memutasi apa pun yang tumpang tindih dengannya, sambil tetap
+// berjalan atas satu dokumen utuh (bukan potongan string terpisah per ruas)
+// sehingga status awal-kalimat tetap benar lintas batas ruas. Rule
+// struktural yang tidak terdaftar di sini (NormalizeSpaces,
+// FixPunctuationSpacing, FixIDRCurrency) selalu berjalan atas dokumen penuh
+// tanpa proteksi: puebi/doc sudah mengenali URL/email sebagai span utuh,
+// jadi titik/garis miring di dalamnya tidak pernah salah dibaca.
+var scopedRuleFns = map[string]func(string, [][2]int) string{
+	"FixPrepositions":     fixCommonPrepositionsScoped,
+	"NormalizeRealTime":   normalizeRealTimeScoped,
+	"CapitalizeSentences": capitalizeSentencesScoped,
+	"FixGreetingNameCase": fixGreetingNameCaseScoped,
+	"DecapitalizeMidSentence": func(s string, protected [][2]int) string {
+		return decapitalizeMidSentenceScoped(s, defaultExceptions(), protectedHeads(), protected)
+	},
+}
+
+// protectedRanges mengembalikan rentang byte [start,end) di s yang bukan
+// lang.KindIndonesian menurut lang.Detect.
+func protectedRanges(s string) [][2]int {
+	segs := lang.Detect(s)
+	var ranges [][2]int
+	pos := 0
+	for _, seg := range segs {
+		n := len(seg.Text)
+		if seg.Kind != lang.KindIndonesian {
+			ranges = append(ranges, [2]int{pos, pos + n})
+		}
+		pos += n
+	}
+	return ranges
+}
+
+// overlapsRange melaporkan apakah [start,end) beririsan dengan salah satu
+// rentang di ranges.
+func overlapsRange(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && end > r[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultExceptions mengekspos defaultExceptions agar bisa dipakai ulang oleh
+// paket lain (mis. puebi/translit untuk melindungi nama diri dari transliterasi).
+func DefaultExceptions() map[string]bool {
+	return defaultExceptions()
+}
+
+// ProtectedHeads mengekspos protectedHeads untuk alasan yang sama dengan
+// DefaultExceptions.
+func ProtectedHeads() map[string]bool {
+	return protectedHeads()
+}
+
+// Change mencatat bahwa sebuah Rule mengubah Document, untuk mode Diagnostics.
+type Change struct {
+	Rule   string
+	Before string
+	After  string
+}
+
+// Diagnostics mengumpulkan Change yang terjadi selama satu Pipeline.Run.
+type Diagnostics struct {
+	Changes []Change
+}
+
+// Pipeline adalah urutan Rule bernama yang dijalankan atas sebuah Document.
+// Pipeline bersifat immutable: With/Without/Replace mengembalikan Pipeline
+// baru, sehingga DefaultPipeline() aman dipakai ulang sebagai basis.
+type Pipeline struct {
+	rules         []Rule
+	diagnostics   bool
+	ignoreForeign bool
+}
+
+// NewPipeline membangun Pipeline kosong atau dari rules yang diberikan.
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: append([]Rule{}, rules...)}
+}
+
+// DefaultPipeline mereproduksi urutan rule yang dipakai SanitizeToPUEBI
+// sebelum Pipeline ada, untuk kompatibilitas mundur.
+func DefaultPipeline() *Pipeline {
+	return NewPipeline(
+		NormalizeSpaces,
+		FixPunctuationSpacing,
+		FixPrepositions,
+		NormalizeRealTime,
+		CapitalizeSentences,
+		FixGreetingNameCase,
+		DecapitalizeMidSentence,
+		FixIDRCurrency,
+	)
+}
+
+func (p *Pipeline) clone() *Pipeline {
+	return &Pipeline{
+		rules:         append([]Rule{}, p.rules...),
+		diagnostics:   p.diagnostics,
+		ignoreForeign: p.ignoreForeign,
+	}
+}
+
+// With mengembalikan Pipeline baru dengan rule ditambahkan di akhir.
+func (p *Pipeline) With(rule Rule) *Pipeline {
+	np := p.clone()
+	np.rules = append(np.rules, rule)
+	return np
+}
+
+// Without mengembalikan Pipeline baru tanpa rule bernama name.
+func (p *Pipeline) Without(name string) *Pipeline {
+	np := p.clone()
+	kept := np.rules[:0]
+	for _, r := range np.rules {
+		if r.Name() != name {
+			kept = append(kept, r)
+		}
+	}
+	np.rules = kept
+	return np
+}
+
+// Replace mengembalikan Pipeline baru dengan rule bernama name diganti oleh r.
+// Bila tidak ada rule bernama name, Pipeline dikembalikan apa adanya.
+func (p *Pipeline) Replace(name string, r Rule) *Pipeline {
+	np := p.clone()
+	for i, old := range np.rules {
+		if old.Name() == name {
+			np.rules[i] = r
+		}
+	}
+	return np
+}
+
+// WithDiagnostics mengembalikan Pipeline baru yang mencatat Change di setiap
+// Run lewat RunWithDiagnostics.
+func (p *Pipeline) WithDiagnostics() *Pipeline {
+	np := p.clone()
+	np.diagnostics = true
+	return np
+}
+
+// IgnoreForeign mengembalikan Pipeline baru yang, bila on, membatasi rule di
+// scopedRuleFns (preposisi, real time, kapitalisasi, dst.) agar hanya
+// berjalan atas ruas berbahasa Indonesia menurut puebi/lang.Detect — ruas
+// Inggris, kode, URL, email, dan angka dilewatkan apa adanya. Rule
+// struktural tetap berjalan atas dokumen penuh seperti biasa. Berguna untuk
+// teks yang mencampur bahasa Indonesia dengan istilah asing yang tidak
+// boleh diutak-atik, mis. "Jangan reply email ini, hubungi support@bank.com".
+func (p *Pipeline) IgnoreForeign(on bool) *Pipeline {
+	np := p.clone()
+	np.ignoreForeign = on
+	return np
+}
+
+// Run menjalankan seluruh Rule secara berurutan dan mengembalikan hasil akhir.
+func (p *Pipeline) Run(s string) string {
+	out, _ := p.run(s)
+	return out
+}
+
+// RunWithDiagnostics berfungsi seperti Run, tetapi juga mengembalikan
+// Diagnostics berisi Rule mana yang mengubah apa. Berguna untuk men-debug
+// pipeline kustom di aplikasi hilir.
+func (p *Pipeline) RunWithDiagnostics(s string) (string, Diagnostics) {
+	return p.run(s)
+}
+
+func (p *Pipeline) run(s string) (string, Diagnostics) {
+	var diag Diagnostics
+	if strings.TrimSpace(s) == "" {
+		return s, diag
+	}
+
+	doc := NewDocument(s)
+	for _, r := range p.rules {
+		before := doc.String()
+		if scopedFn, ok := scopedRuleFns[r.Name()]; p.ignoreForeign && ok {
+			doc.Set(scopedFn(doc.String(), protectedRanges(doc.String())))
+		} else {
+			r.Apply(doc)
+		}
+		if p.diagnostics {
+			if after := doc.String(); after != before {
+				diag.Changes = append(diag.Changes, Change{Rule: r.Name(), Before: before, After: after})
+			}
+		}
+	}
+	return strings.TrimSpace(doc.String()), diag
+}
@@ -0,0 +1,45 @@
+package puebi
+
+import "testing"
+
+func TestPipelineWithout(t *testing.T) {
+	p := DefaultPipeline().Without("CapitalizeSentences")
+	got := p.Run("ini kalimat tanpa kapitalisasi.")
+	want := "ini kalimat tanpa kapitalisasi."
+	if got != want {
+		t.Errorf("Run() = %q, want %q", got, want)
+	}
+}
+
+func TestPipelineWithAddsRuleAtEnd(t *testing.T) {
+	calls := 0
+	marker := newRule("Marker", func(s string) string {
+		calls++
+		return s
+	})
+	p := DefaultPipeline().With(marker)
+	p.Run("Halo dunia.")
+	if calls != 1 {
+		t.Errorf("marker rule called %d times, want 1", calls)
+	}
+}
+
+func TestPipelineReplace(t *testing.T) {
+	p := DefaultPipeline().Replace("FixIDRCurrency", newRule("FixIDRCurrency", func(s string) string {
+		return s + "!"
+	}))
+	got := p.Run("Halo dunia")
+	want := "Halo dunia!"
+	if got != want {
+		t.Errorf("Run() = %q, want %q", got, want)
+	}
+}
+
+func TestPipelineReplaceUnknownNameIsNoop(t *testing.T) {
+	p := DefaultPipeline().Replace("TidakAda", newRule("TidakAda", func(s string) string { return "diganti" }))
+	got := p.Run("Halo dunia.")
+	want := DefaultPipeline().Run("Halo dunia.")
+	if got != want {
+		t.Errorf("Replace with unknown name changed behavior: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+// Package translit mengonversi ejaan Indonesia pra-EYD (Van Ophuijsen 1901,
+// Soewandi 1947) ke ejaan PUEBI modern, mis. "oe" -> "u", "dj" -> "j",
+// "tj" -> "c".
+package translit
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/manhax/puebi/puebi"
+)
+
+// Scheme menyatakan ejaan lama sumber teks.
+type Scheme int
+
+const (
+	// Auto mencoba mengenali campuran Van Ophuijsen & Soewandi sekaligus;
+	// ini aman dipakai sebagai default karena kedua aturan saling tidak
+	// tumpang tindih kecuali untuk "oe", yang tetap diproses.
+	Auto Scheme = iota
+	// VanOphuijsen adalah ejaan 1901 (masih memakai "oe").
+	VanOphuijsen
+	// Soewandi adalah ejaan 1947 (sudah "u", tapi masih dj/tj/nj/sj/j/ch).
+	Soewandi
+)
+
+func (sc Scheme) String() string {
+	switch sc {
+	case VanOphuijsen:
+		return "VanOphuijsen"
+	case Soewandi:
+		return "Soewandi"
+	default:
+		return "Auto"
+	}
+}
+
+// Options mengatur perilaku ToPUEBI.
+type Options struct {
+	// Source adalah skema ejaan lama sumber teks.
+	Source Scheme
+	// PreserveProperNouns, bila true, membiarkan nama diri ejaan lama yang
+	// dikenal (mis. "Soekarno", "Djakarta") apa adanya, memakai mekanisme
+	// pengecualian yang sama dengan puebi.DefaultExceptions/ProtectedHeads.
+	PreserveProperNouns bool
+}
+
+// properNouns adalah ejaan lama nama diri yang tidak boleh ikut
+// ditransliterasi kecuali caller menonaktifkan PreserveProperNouns.
+var properNouns = map[string]bool{
+	"Soekarno":   true,
+	"Soeharto":   true,
+	"Djakarta":   true,
+	"Jogjakarta": true,
+	"Bandoeng":   true,
+	"Soerabaja":  true,
+	"Moehammad":  true,
+	"Oesman":     true,
+}
+
+type rule struct {
+	old     string
+	new     string
+	schemes []Scheme // kosong berarti berlaku untuk semua skema
+}
+
+func (r rule) appliesTo(sc Scheme) bool {
+	if len(r.schemes) == 0 {
+		return true
+	}
+	for _, s := range r.schemes {
+		if s == sc {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardRules diurutkan dari pola terpanjang ke terpendek agar digraf
+// (dj, tj, nj, sj, ch, oe) selalu diperiksa sebelum huruf tunggal "j", jadi
+// "j" -> "y" hanya berlaku bila bukan bagian dari salah satu digraf tersebut.
+var forwardRules = []rule{
+	{old: "oe", new: "u", schemes: []Scheme{Auto, VanOphuijsen}},
+	{old: "tj", new: "c"},
+	{old: "dj", new: "j"},
+	{old: "nj", new: "ny"},
+	{old: "sj", new: "sy"},
+	{old: "ch", new: "kh"},
+	{old: "j", new: "y"},
+}
+
+// reverseRules adalah kebalikan mekanis forwardRules, dipakai ToOldOrthography
+// untuk uji round-trip. Tidak sepenuhnya lossless
+// secara linguistik (mis. "u" yang sudah ada sebelum transliterasi juga ikut
+// berubah jadi "oe"), tapi cukup untuk memverifikasi bahwa aturan maju
+// konsisten dengan kebalikannya pada kata-kata tak ambigu.
+var reverseRules = []rule{
+	{old: "kh", new: "ch"},
+	{old: "ny", new: "nj"},
+	{old: "sy", new: "sj"},
+	{old: "y", new: "j"},
+	{old: "c", new: "tj"},
+	{old: "j", new: "dj"},
+	{old: "u", new: "oe", schemes: []Scheme{Auto, VanOphuijsen}},
+}
+
+var reWord = regexp.MustCompile(`[\p{L}']+`)
+
+var diacritics = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'Á': 'A', 'À': 'A', 'Â': 'A', 'Ä': 'A',
+	'É': 'E', 'È': 'E', 'Ê': 'E', 'Ë': 'E',
+	'Í': 'I', 'Ì': 'I', 'Î': 'I', 'Ï': 'I',
+	'Ó': 'O', 'Ò': 'O', 'Ô': 'O', 'Ö': 'O',
+	'Ú': 'U', 'Ù': 'U', 'Û': 'U', 'Ü': 'U',
+}
+
+var reApostropheBetweenVowels = regexp.MustCompile(`(?i)([aiueo])'([aiueo])`)
+
+// ToPUEBI mentransliterasikan s dari ejaan lama (sesuai opts.Source) ke
+// ejaan PUEBI modern.
+func ToPUEBI(s string, opts Options) string {
+	return reWord.ReplaceAllStringFunc(s, func(word string) string {
+		if opts.PreserveProperNouns && isProtectedProperNoun(word) {
+			return word
+		}
+		return transliterate(word, opts.Source, forwardRules)
+	})
+}
+
+// ToOldOrthography menerapkan kebalikan mekanis dari ToPUEBI, mengembalikan
+// teks PUEBI ke ejaan lama target. Disediakan untuk uji round-trip (paket
+// puebitest tidak memanggilnya), bukan untuk transliterasi produksi dua arah
+// yang akurat.
+func ToOldOrthography(s string, target Scheme) string {
+	return reWord.ReplaceAllStringFunc(s, func(word string) string {
+		return transliterate(word, target, reverseRules)
+	})
+}
+
+// Rule membungkus ToPUEBI sebagai puebi.Rule, sehingga transliterasi ejaan
+// lama bisa dipasang sebagai langkah opsional di puebi.Pipeline, mis.:
+//
+//	pipeline := puebi.DefaultPipeline().With(translit.Rule(translit.Options{PreserveProperNouns: true}))
+func Rule(opts Options) puebi.Rule {
+	return translitRule{opts: opts}
+}
+
+type translitRule struct{ opts Options }
+
+func (r translitRule) Name() string { return "Translit" }
+
+func (r translitRule) Apply(d *puebi.Document) {
+	d.Set(ToPUEBI(d.String(), r.opts))
+}
+
+func isProtectedProperNoun(word string) bool {
+	if properNouns[word] {
+		return true
+	}
+	if puebi.DefaultExceptions()[word] {
+		return true
+	}
+	if puebi.ProtectedHeads()[word] {
+		return true
+	}
+	return false
+}
+
+func transliterate(word string, scheme Scheme, table []rule) string {
+	word = reApostropheBetweenVowels.ReplaceAllString(word, "$1$2")
+	word = stripDiacritics(word)
+	return applyRules(word, scheme, table)
+}
+
+func stripDiacritics(s string) string {
+	return strings.Map(func(r rune) rune {
+		if repl, ok := diacritics[r]; ok {
+			return repl
+		}
+		return r
+	}, s)
+}
+
+func applyRules(word string, scheme Scheme, table []rule) string {
+	runes := []rune(word)
+	n := len(runes)
+	var b strings.Builder
+
+	for i := 0; i < n; {
+		matched := false
+		for _, r := range table {
+			if !r.appliesTo(scheme) {
+				continue
+			}
+			ol := []rune(r.old)
+			if i+len(ol) > n {
+				continue
+			}
+			if strings.EqualFold(string(runes[i:i+len(ol)]), r.old) {
+				b.WriteString(matchCase(string(runes[i:i+len(ol)]), r.new))
+				i += len(ol)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteRune(runes[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// matchCase menyalin pola kapitalisasi orig (UPPER/Title/lower) ke repl.
+func matchCase(orig, repl string) string {
+	if orig == strings.ToUpper(orig) && orig != strings.ToLower(orig) {
+		return strings.ToUpper(repl)
+	}
+	origRunes := []rune(orig)
+	if len(origRunes) > 0 && unicode.IsUpper(origRunes[0]) {
+		replRunes := []rune(strings.ToLower(repl))
+		replRunes[0] = unicode.ToUpper(replRunes[0])
+		return string(replRunes)
+	}
+	return strings.ToLower(repl)
+}
@@ -0,0 +1,35 @@
+package translit
+
+import "testing"
+
+func TestToPUEBIVanOphuijsen(t *testing.T) {
+	got := ToPUEBI("Soekarno tinggal di Djakarta, doeloe djaja.", Options{Source: VanOphuijsen})
+	want := "Sukarno tinggal di Jakarta, dulu jaya."
+	if got != want {
+		t.Errorf("ToPUEBI() = %q, want %q", got, want)
+	}
+}
+
+func TestToPUEBIPreserveProperNouns(t *testing.T) {
+	got := ToPUEBI("Soekarno tinggal di Djakarta.", Options{Source: VanOphuijsen, PreserveProperNouns: true})
+	want := "Soekarno tinggal di Djakarta."
+	if got != want {
+		t.Errorf("ToPUEBI() with PreserveProperNouns = %q, want %q", got, want)
+	}
+}
+
+func TestToPUEBISoewandiDigraphs(t *testing.T) {
+	got := ToPUEBI("tjatatan njonya sjarat", Options{Source: Soewandi})
+	want := "catatan nyonya syarat"
+	if got != want {
+		t.Errorf("ToPUEBI() = %q, want %q", got, want)
+	}
+}
+
+func TestToPUEBIPreservesCapitalizationPattern(t *testing.T) {
+	got := ToPUEBI("Tjatatan", Options{Source: Soewandi})
+	want := "Catatan"
+	if got != want {
+		t.Errorf("ToPUEBI() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+package puebitest
+
+// Corpus adalah contoh kalimat Indonesia yang dipakai tes idempotensi dan
+// sebagai seed korpus FuzzSanitizeIdempotent. Sengaja mencampur: kata
+// berimbuhan di-/ke-, singkatan, nominal Rupiah, elipsis, dan kapitalisasi
+// nyasar, karena itulah kombinasi yang paling sering membuat rule saling
+// bertabrakan.
+var Corpus = []string{
+	"Hai luqmanul hakim, Anda telah melakukan Transfer Real Time dari rekening 1023613267 sejumlah Rp 12.000.",
+	"Pastikan transaksi ini benar dilakukan atau Hubungi Call Center 1500 035.",
+	"Dirumahku sedang ada acara keluarga besar.",
+	"Kesekolahnya dia berjalan kaki setiap pagi.",
+	"Silakan datang ke kantor cabang Bank Sampoerna di Jalan Sudirman.",
+	"Dr. Andi bertugas di puskesmas hingga pukul 17.00.",
+	"Sesuai No. 12 Tahun 2020, pembayaran paling lambat tgl. 5 setiap bulan.",
+	"Biaya admin sebesar Rp5.000 akan dipotong otomatis...",
+	"Apakah Anda yakin ingin melanjutkan transaksi ini?",
+	"INFO PENTING: saldo ATM Anda kurang dari Rp50.000.",
+}
@@ -0,0 +1,33 @@
+package puebitest
+
+import "testing"
+
+// FuzzSanitizeIdempotent menabur mutasi dari korpus seed (kalimat lengkap
+// ditambah potongan kecil ala tata bahasa Indonesia: kata, tanda baca,
+// nominal Rp, dan campuran kapitalisasi) lalu memverifikasi SanitizeToPUEBI
+// tetap idempotent untuk setiap input yang dihasilkan fuzzer.
+func FuzzSanitizeIdempotent(f *testing.F) {
+	for _, c := range Corpus {
+		f.Add(c)
+	}
+
+	seeds := []string{
+		"hai", "Hai", "HAI",
+		"dirumah", "dirumahku", "kesekolahnya", "dibayar", "kehilangan",
+		"di", "ke", "kepada", "daripada",
+		"Rp", "Rp12.000", "rp 12.000", "RP.   12.000",
+		"real time", "Real-Time", "REALTIME",
+		"dr.", "Jl.", "No.", "hlm.",
+		"...", "….", "!!!", "???",
+		"'", "\"", "—",
+		"",
+		"   ",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		AssertIdempotent(t, s)
+	})
+}
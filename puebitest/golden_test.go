@@ -0,0 +1,55 @@
+package puebitest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manhax/puebi/puebi"
+)
+
+var update = flag.Bool("update", false, "perbarui file golden di testdata/golden sesuai output SanitizeToPUEBI saat ini")
+
+// TestGolden menjalankan SanitizeToPUEBI atas setiap testdata/golden/*.input
+// dan membandingkannya dengan pasangan *.golden. Jalankan dengan -update
+// untuk menulis ulang golden sesuai perilaku saat ini setelah perubahan rule
+// yang disengaja, lalu review diffnya.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/golden/*.input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("tidak ada berkas testdata/golden/*.input ditemukan")
+	}
+
+	for _, inPath := range inputs {
+		inPath := inPath
+		name := strings.TrimSuffix(filepath.Base(inPath), ".input")
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(inPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := puebi.SanitizeToPUEBI(string(input))
+			goldenPath := filepath.Join("testdata", "golden", name+".golden")
+
+			if *update {
+				if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("golden %s tidak cocok:\n got:  %q\n want: %q", name, got, string(want))
+			}
+		})
+	}
+}
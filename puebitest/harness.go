@@ -0,0 +1,73 @@
+// Package puebitest menyediakan kerangka uji properti untuk puebi,
+// meminjam gagasan uji round-trip dari suite uji transliterasi Unicode:
+// idempotensi, konsistensi terhadap spasi akhir, dan kestabilan konten
+// numerik, ditambah korpus bersama untuk tes unit, golden file, dan fuzzing.
+package puebitest
+
+import (
+	"github.com/manhax/puebi/puebi"
+	"github.com/manhax/puebi/puebi/doc"
+)
+
+// TB adalah subset testing.TB yang dipakai harness ini, supaya paket ini
+// tidak wajib diimpor hanya dari file _test.go.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertIdempotent memastikan SanitizeToPUEBI(SanitizeToPUEBI(x)) ==
+// SanitizeToPUEBI(x): menyapu ulang hasil yang sudah rapi tidak boleh
+// mengubahnya lagi.
+func AssertIdempotent(t TB, input string) {
+	t.Helper()
+	once := puebi.SanitizeToPUEBI(input)
+	twice := puebi.SanitizeToPUEBI(once)
+	if once != twice {
+		t.Errorf("SanitizeToPUEBI tidak idempotent untuk %q:\n  1x: %q\n  2x: %q", input, once, twice)
+	}
+}
+
+// AssertCommutesWithTrailingWhitespace memastikan menambah spasi/tab di
+// akhir input tidak mengubah hasil sanitasi.
+func AssertCommutesWithTrailingWhitespace(t TB, input string) {
+	t.Helper()
+	base := puebi.SanitizeToPUEBI(input)
+	padded := puebi.SanitizeToPUEBI(input + "   \t")
+	if base != padded {
+		t.Errorf("SanitizeToPUEBI tidak konsisten terhadap spasi akhir untuk %q:\n  tanpa:  %q\n  dengan: %q", input, base, padded)
+	}
+}
+
+// AssertNumericContentPreserved memastikan deretan token numerik (NumberSpan
+// dan nominal CurrencySpan) pada input tetap identik setelah SanitizeToPUEBI;
+// ini adalah properti utama yang dijanjikan model Document berbasis span:
+// rule lain tidak boleh salah membaca/memotong angka.
+func AssertNumericContentPreserved(t TB, input string) {
+	t.Helper()
+	before := numericTokens(input)
+	after := numericTokens(puebi.SanitizeToPUEBI(input))
+	if len(before) != len(after) {
+		t.Errorf("jumlah token numerik berubah untuk %q: sebelum=%v sesudah=%v", input, before, after)
+		return
+	}
+	for i := range before {
+		if before[i] != after[i] {
+			t.Errorf("token numerik ke-%d berubah untuk %q: %q -> %q", i, input, before[i], after[i])
+		}
+	}
+}
+
+func numericTokens(s string) []string {
+	d := doc.Parse(s)
+	var out []string
+	for _, sp := range d.Spans {
+		switch v := sp.(type) {
+		case *doc.NumberSpan:
+			out = append(out, v.Text)
+		case *doc.CurrencySpan:
+			out = append(out, v.Amount)
+		}
+	}
+	return out
+}
@@ -0,0 +1,14 @@
+package puebitest
+
+import "testing"
+
+func TestCorpusIdempotence(t *testing.T) {
+	for _, input := range Corpus {
+		input := input
+		t.Run(input, func(t *testing.T) {
+			AssertIdempotent(t, input)
+			AssertCommutesWithTrailingWhitespace(t, input)
+			AssertNumericContentPreserved(t, input)
+		})
+	}
+}